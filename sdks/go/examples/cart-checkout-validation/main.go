@@ -1,58 +1,47 @@
 package main
 
 import (
+	"context"
+
 	sf "github.com/Shopify/shopify-function-wasm-api/sdks/go/shopify_function"
 )
 
-func collectErrors(cart sf.Value) bool {
-	if _, ok := cart.ObjLen(); !ok {
-		return false
-	}
+type line struct {
+	Quantity float64 `sf:"quantity"`
+}
 
-	lines := cart.GetObjProp("lines")
-	linesLen, ok := lines.ArrayLen()
-	if !ok {
-		return false
-	}
+type cart struct {
+	Lines []line `sf:"lines"`
+}
 
-	for i := uint32(0); i < linesLen; i++ {
-		line := lines.GetAtIndex(i)
-		if _, ok := line.ObjLen(); ok {
-			quantity := line.GetObjProp("quantity")
-			if q, ok := quantity.AsNumber(); ok {
-				if q > 1.0 {
-					return true
-				}
-			}
-		}
-	}
+type input struct {
+	Cart cart `sf:"cart"`
+}
 
-	return false
+type functionError struct {
+	LocalizedMessage string `sf:"localizedMessage"`
+	Target           string `sf:"target"`
 }
 
-func main() {
-	input := sf.InputGet()
-	cart := input.GetObjProp("cart")
-	hasError := collectErrors(cart)
-
-	// {"errors": [...]}
-	sf.OutputObject(1)
-	sf.OutputString("errors")
-
-	if hasError {
-		sf.OutputArray(1)
-		// {"localizedMessage": "...", "target": "$.cart"}
-		sf.OutputObject(2)
-		sf.OutputString("localizedMessage")
-		sf.OutputString("Not possible to order more than one of each")
-		sf.OutputString("target")
-		sf.OutputString("$.cart")
-		sf.OutputFinishObject()
-		sf.OutputFinishArray()
-	} else {
-		sf.OutputArray(0)
-		sf.OutputFinishArray()
+type output struct {
+	Errors []functionError `sf:"errors"`
+}
+
+func validate(_ context.Context, in input) (output, error) {
+	out := output{Errors: []functionError{}}
+	for _, l := range in.Cart.Lines {
+		if l.Quantity > 1.0 {
+			out.Errors = append(out.Errors, functionError{
+				LocalizedMessage: "Not possible to order more than one of each",
+				Target:           sf.Root().Field("cart").String(),
+			})
+			break
+		}
 	}
+	return out, nil
+}
 
-	sf.OutputFinishObject()
+func main() {
+	sf.Handle(validate)
+	sf.Run()
 }
@@ -1,86 +1,27 @@
 package main
 
 import (
+	"context"
+
 	sf "github.com/Shopify/shopify-function-wasm-api/sdks/go/shopify_function"
-	"math"
 )
 
-var internedFoo uint32
-var internedBar uint32
-
-func echoValue(val sf.Value) {
-	switch val.Tag() {
-	case sf.TagNull:
-		sf.OutputNull()
-
-	case sf.TagBool:
-		b, _ := val.AsBool()
-		sf.OutputBool(b)
-
-	case sf.TagNumber:
-		num, _ := val.AsNumber()
-		truncated := math.Trunc(num)
-		if truncated == num && num >= -2147483648.0 && num <= 2147483647.0 {
-			sf.OutputI32(int32(num))
-		} else {
-			sf.OutputF64(num)
-		}
-
-	case sf.TagString:
-		l := val.StringLen()
-		buf := make([]byte, l)
-		val.ReadString(buf)
-		sf.OutputStringBytes(buf)
-
-	case sf.TagObject:
-		l, _ := val.ObjLen()
-		sf.OutputObject(l)
-		for i := uint32(0); i < l; i++ {
-			key := val.GetObjKeyAtIndex(i)
-			keyLen := key.StringLen()
-			keyBuf := make([]byte, keyLen)
-			key.ReadString(keyBuf)
-			keyStr := string(keyBuf)
-
-			if keyStr == "foo" {
-				// Use interned string for key and interned obj prop for value
-				sf.OutputInternedString(internedFoo)
-				child := val.GetInternedObjProp(internedFoo)
-				echoValue(child)
-			} else if keyStr == "bar" {
-				sf.OutputInternedString(internedBar)
-				child := val.GetInternedObjProp(internedBar)
-				echoValue(child)
-			} else {
-				sf.OutputStringBytes(keyBuf)
-				child := val.GetAtIndex(i)
-				echoValue(child)
-			}
-		}
-		sf.OutputFinishObject()
-
-	case sf.TagArray:
-		l, _ := val.ArrayLen()
-		sf.OutputArray(l)
-		for i := uint32(0); i < l; i++ {
-			child := val.GetAtIndex(i)
-			echoValue(child)
-		}
-		sf.OutputFinishArray()
+// echoed only covers "foo" and "bar"; the interned-string fast path is a
+// property of named `sf` struct fields, not of arbitrary keys found while
+// walking a decoded input, so this no longer echoes the rest of the input
+// tree the way the hand-rolled version did.
+type echoed struct {
+	Foo any `sf:"foo,omitempty,intern"`
+	Bar any `sf:"bar,omitempty,intern"`
+}
 
-	default:
-		sf.OutputNull()
-	}
+func echo(_ context.Context, in echoed) (echoed, error) {
+	return in, nil
 }
 
 func main() {
-	// Intern strings at startup
-	internedFoo = sf.InternString("foo")
-	internedBar = sf.InternString("bar")
-
-	// Log to exercise log API
+	sf.InternKeys("foo", "bar")
 	sf.Log("interned-echo")
-
-	input := sf.InputGet()
-	echoValue(input)
+	sf.Handle(echo)
+	sf.Run()
 }
@@ -0,0 +1,459 @@
+// Package hostvalue is an in-memory stand-in for the Shopify Function wasm
+// host. It backs the pure-Go (non-wasm) build of shopify_function's import
+// functions so functions can be exercised with `go test` instead of a
+// wasm32 build and a real host, and is shared with shopifyfunctiontest,
+// which drives it.
+package hostvalue
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Constants mirror the NaN-boxing scheme in shopify_function/value.go; a
+// change to the layout there must be reflected here too.
+const (
+	nanMask        uint64 = 0x7FFC000000000000
+	tagShift              = 46
+	valueEncSize          = 32
+	maxValueLength uint32 = 16383
+)
+
+// Tag mirrors shopify_function.ValueTag.
+type Tag uint8
+
+const (
+	TagNull   Tag = 0
+	TagBool   Tag = 1
+	TagNumber Tag = 2
+	TagString Tag = 3
+	TagObject Tag = 4
+	TagArray  Tag = 5
+)
+
+// Object is an ordered key/value tree node. Plain Go maps can't stand in
+// for JSON objects because map iteration order is random; Object keeps
+// the source order so round-tripping through a function looks the same
+// to a human reading the output JSON.
+type Object struct {
+	Keys []string
+	Vals []any
+}
+
+// State is the host-side value model for a single function invocation: an
+// input tree, the table of registered string/object/array handles it was
+// decomposed into, the shared intern table, the output being assembled,
+// and any log lines written during the invocation.
+type State struct {
+	nodes    []any
+	interned []string
+	input    any
+
+	outStack []outFrame
+	outRoot  any
+
+	logs []string
+}
+
+type outFrame struct {
+	isObject   bool
+	keys       []string
+	vals       []any
+	pendingKey *string
+}
+
+// NewState returns an empty host, with no input and no output.
+func NewState() *State {
+	return &State{}
+}
+
+// Reset discards any output, logs, and handles left over from a previous
+// invocation and sets input as the new root input value.
+func (s *State) Reset(input any) {
+	s.nodes = nil
+	s.interned = nil
+	s.input = input
+	s.outStack = nil
+	s.outRoot = nil
+	s.logs = nil
+}
+
+func (s *State) register(v any) uint32 {
+	s.nodes = append(s.nodes, v)
+	return uint32(len(s.nodes) - 1)
+}
+
+func ptrOf(raw int64) uint32 {
+	return uint32(uint64(raw))
+}
+
+func (s *State) encode(v any) int64 {
+	switch val := v.(type) {
+	case nil:
+		return int64(nanMask)
+	case bool:
+		bits := nanMask | uint64(TagBool)<<tagShift
+		if val {
+			bits |= 1
+		}
+		return int64(bits)
+	case float64:
+		return int64(math.Float64bits(val))
+	case string:
+		return int64(s.encodeTagged(TagString, uint32(len(val)), s.register(val)))
+	case *Object:
+		return int64(s.encodeTagged(TagObject, uint32(len(val.Keys)), s.register(val)))
+	case []any:
+		return int64(s.encodeTagged(TagArray, uint32(len(val)), s.register(val)))
+	default:
+		panic(fmt.Sprintf("hostvalue: unsupported tree value %T", v))
+	}
+}
+
+func (s *State) encodeTagged(tag Tag, length, idx uint32) uint64 {
+	inline := length
+	if inline > maxValueLength {
+		inline = maxValueLength
+	}
+	return nanMask | uint64(tag)<<tagShift | uint64(inline)<<valueEncSize | uint64(idx)
+}
+
+// Root returns the encoded root input value.
+func (s *State) Root() int64 {
+	return s.encode(s.input)
+}
+
+// ValLen returns the true length of the string/object/array at scope,
+// used for the overflow path when the inline length field saturates at
+// maxValueLength.
+func (s *State) ValLen(scope int64) uint32 {
+	switch n := s.nodes[ptrOf(scope)].(type) {
+	case string:
+		return uint32(len(n))
+	case *Object:
+		return uint32(len(n.Keys))
+	case []any:
+		return uint32(len(n))
+	default:
+		return 0
+	}
+}
+
+// ReadUTF8 copies the bytes of the string at scope into out.
+func (s *State) ReadUTF8(scope uint32, out []byte) {
+	if str, ok := s.nodes[scope].(string); ok {
+		copy(out, str)
+	}
+}
+
+// ObjProp returns the value of the named property on the object at scope,
+// or null if scope isn't an object or has no such key.
+func (s *State) ObjProp(scope int64, name string) int64 {
+	bits := uint64(scope)
+	if bits&nanMask != nanMask || Tag((bits>>tagShift)&0xF) != TagObject {
+		return s.encode(nil)
+	}
+	obj, ok := s.nodes[ptrOf(scope)].(*Object)
+	if !ok {
+		return s.encode(nil)
+	}
+	for i, key := range obj.Keys {
+		if key == name {
+			return s.encode(obj.Vals[i])
+		}
+	}
+	return s.encode(nil)
+}
+
+// InternedObjProp looks up a property keyed by a previously interned
+// string ID.
+func (s *State) InternedObjProp(scope int64, id uint32) int64 {
+	if int(id) >= len(s.interned) {
+		return s.encode(nil)
+	}
+	return s.ObjProp(scope, s.interned[id])
+}
+
+// AtIndex returns the element at index of the array at scope, or the
+// index-th value of the object at scope (paired with KeyAtIndex).
+func (s *State) AtIndex(scope int64, index uint32) int64 {
+	switch n := s.nodes[ptrOf(scope)].(type) {
+	case []any:
+		return s.encode(n[index])
+	case *Object:
+		return s.encode(n.Vals[index])
+	default:
+		return s.encode(nil)
+	}
+}
+
+// KeyAtIndex returns the index-th key of the object at scope.
+func (s *State) KeyAtIndex(scope int64, index uint32) int64 {
+	obj, ok := s.nodes[ptrOf(scope)].(*Object)
+	if !ok {
+		return s.encode(nil)
+	}
+	return s.encode(obj.Keys[index])
+}
+
+// Intern registers name in the shared intern table, deduping against
+// previous calls, and returns its ID.
+func (s *State) Intern(name string) uint32 {
+	for i, existing := range s.interned {
+		if existing == name {
+			return uint32(i)
+		}
+	}
+	s.interned = append(s.interned, name)
+	return uint32(len(s.interned) - 1)
+}
+
+func (s *State) push(v any) {
+	if len(s.outStack) == 0 {
+		s.outRoot = v
+		return
+	}
+	top := &s.outStack[len(s.outStack)-1]
+	if top.isObject {
+		if top.pendingKey == nil {
+			key, _ := v.(string)
+			top.pendingKey = &key
+			return
+		}
+		top.keys = append(top.keys, *top.pendingKey)
+		top.vals = append(top.vals, v)
+		top.pendingKey = nil
+		return
+	}
+	top.vals = append(top.vals, v)
+}
+
+// OutputBool appends a bool to the output being assembled.
+func (s *State) OutputBool(v bool) { s.push(v) }
+
+// OutputNull appends null to the output being assembled.
+func (s *State) OutputNull() { s.push(nil) }
+
+// OutputI32 appends an integer to the output being assembled.
+func (s *State) OutputI32(v int32) { s.push(float64(v)) }
+
+// OutputF64 appends a float to the output being assembled.
+func (s *State) OutputF64(v float64) { s.push(v) }
+
+// OutputString appends a string to the output being assembled.
+func (s *State) OutputString(v string) { s.push(v) }
+
+// OutputInternedString appends the string previously interned under id.
+func (s *State) OutputInternedString(id uint32) {
+	if int(id) < len(s.interned) {
+		s.push(s.interned[id])
+		return
+	}
+	s.push("")
+}
+
+// OutputNewObject opens an object with the given number of key/value
+// pairs; subsequent Output* calls alternate key, value until
+// OutputFinishObject.
+func (s *State) OutputNewObject(length uint32) {
+	s.outStack = append(s.outStack, outFrame{isObject: true, keys: make([]string, 0, length), vals: make([]any, 0, length)})
+}
+
+// OutputFinishObject closes the object opened by the matching
+// OutputNewObject and appends it to its parent (or sets it as the root).
+// It reports false, leaving the stack untouched, if there's no matching
+// open object — a stray OutputFinishObject, or one that closes an open
+// array instead — the same class of mismatched-call bug a real host
+// would reject rather than the wasm runtime trapping on out-of-bounds
+// memory.
+func (s *State) OutputFinishObject() bool {
+	if len(s.outStack) == 0 {
+		return false
+	}
+	top := s.outStack[len(s.outStack)-1]
+	if !top.isObject {
+		return false
+	}
+	s.outStack = s.outStack[:len(s.outStack)-1]
+	s.push(&Object{Keys: top.keys, Vals: top.vals})
+	return true
+}
+
+// OutputNewArray opens an array with the given number of elements;
+// subsequent Output* calls append elements until OutputFinishArray.
+func (s *State) OutputNewArray(length uint32) {
+	s.outStack = append(s.outStack, outFrame{vals: make([]any, 0, length)})
+}
+
+// OutputFinishArray closes the array opened by the matching
+// OutputNewArray and appends it to its parent (or sets it as the root).
+// It reports false, leaving the stack untouched, if there's no matching
+// open array.
+func (s *State) OutputFinishArray() bool {
+	if len(s.outStack) == 0 {
+		return false
+	}
+	top := s.outStack[len(s.outStack)-1]
+	if top.isObject {
+		return false
+	}
+	s.outStack = s.outStack[:len(s.outStack)-1]
+	s.push(top.vals)
+	return true
+}
+
+// Output returns the root of the output tree assembled so far: nil,
+// bool, float64, string, *Object, or []any.
+func (s *State) Output() any {
+	return s.outRoot
+}
+
+// Log appends msg to the log lines recorded during this invocation.
+func (s *State) Log(msg string) {
+	s.logs = append(s.logs, msg)
+}
+
+// Logs returns the log lines recorded during this invocation.
+func (s *State) Logs() []string {
+	return s.logs
+}
+
+// FromJSON decodes data into a tree of nil, bool, float64, string,
+// *Object, and []any values, preserving object key order.
+func FromJSON(data []byte) (any, error) {
+	dec := json.NewDecoder(strings.NewReader(string(data)))
+	dec.UseNumber()
+	v, err := decodeValue(dec)
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func decodeValue(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	return decodeToken(dec, tok)
+}
+
+func decodeToken(dec *json.Decoder, tok json.Token) (any, error) {
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			obj := &Object{}
+			for dec.More() {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				key, _ := keyTok.(string)
+				val, err := decodeValue(dec)
+				if err != nil {
+					return nil, err
+				}
+				obj.Keys = append(obj.Keys, key)
+				obj.Vals = append(obj.Vals, val)
+			}
+			if _, err := dec.Token(); err != nil { // consume '}'
+				return nil, err
+			}
+			return obj, nil
+		case '[':
+			var arr []any
+			for dec.More() {
+				val, err := decodeValue(dec)
+				if err != nil {
+					return nil, err
+				}
+				arr = append(arr, val)
+			}
+			if _, err := dec.Token(); err != nil { // consume ']'
+				return nil, err
+			}
+			return arr, nil
+		default:
+			return nil, fmt.Errorf("hostvalue: unexpected delimiter %v", t)
+		}
+	case json.Number:
+		f, err := t.Float64()
+		if err != nil {
+			return nil, err
+		}
+		return f, nil
+	case string, bool, nil:
+		return t, nil
+	default:
+		return nil, fmt.Errorf("hostvalue: unexpected token %v", tok)
+	}
+}
+
+// ToJSON encodes a tree produced by State.Output (or FromJSON) back into
+// JSON text.
+func ToJSON(tree any) (string, error) {
+	var b strings.Builder
+	if err := writeJSON(&b, tree); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func writeJSON(b *strings.Builder, v any) error {
+	switch val := v.(type) {
+	case nil:
+		b.WriteString("null")
+	case bool:
+		if val {
+			b.WriteString("true")
+		} else {
+			b.WriteString("false")
+		}
+	case float64:
+		buf, err := json.Marshal(val)
+		if err != nil {
+			return err
+		}
+		b.Write(buf)
+	case string:
+		buf, err := json.Marshal(val)
+		if err != nil {
+			return err
+		}
+		b.Write(buf)
+	case []any:
+		b.WriteByte('[')
+		for i, e := range val {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			if err := writeJSON(b, e); err != nil {
+				return err
+			}
+		}
+		b.WriteByte(']')
+	case *Object:
+		b.WriteByte('{')
+		for i, k := range val.Keys {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			keyBuf, err := json.Marshal(k)
+			if err != nil {
+				return err
+			}
+			b.Write(keyBuf)
+			b.WriteByte(':')
+			if err := writeJSON(b, val.Vals[i]); err != nil {
+				return err
+			}
+		}
+		b.WriteByte('}')
+	default:
+		return fmt.Errorf("hostvalue: cannot encode %T as JSON", v)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package shopify_function
+
+import "container/list"
+
+// propCacheKey identifies a resolved child Value by its parent's raw
+// handle and the interned ID of the property name, so the same
+// (parent, key) pair always hits the same cache entry regardless of
+// whether it was reached through GetObjProp or GetInternedObjProp.
+type propCacheKey struct {
+	parent int64
+	id     uint32
+}
+
+type propCacheEntry struct {
+	key propCacheKey
+	val Value
+}
+
+var (
+	propCacheSize  = 4096
+	propCacheItems = map[propCacheKey]*list.Element{}
+	propCacheOrder = list.New()
+)
+
+// SetPropCacheSize changes the maximum number of resolved property
+// handles CachedValue keeps per input tree. It also clears the current
+// cache. size <= 0 disables caching entirely.
+func SetPropCacheSize(size int) {
+	propCacheSize = size
+	resetPropCache()
+}
+
+// resetPropCache drops every cached handle. It's called from InputGet so
+// handles resolved against a previous input tree can never be returned
+// for the new one.
+func resetPropCache() {
+	propCacheItems = make(map[propCacheKey]*list.Element)
+	propCacheOrder = list.New()
+}
+
+func getPropCache(key propCacheKey) (Value, bool) {
+	el, ok := propCacheItems[key]
+	if !ok {
+		return Value{}, false
+	}
+	propCacheOrder.MoveToFront(el)
+	return el.Value.(*propCacheEntry).val, true
+}
+
+func putPropCache(key propCacheKey, val Value) {
+	if propCacheSize <= 0 {
+		return
+	}
+	if el, ok := propCacheItems[key]; ok {
+		el.Value.(*propCacheEntry).val = val
+		propCacheOrder.MoveToFront(el)
+		return
+	}
+	propCacheItems[key] = propCacheOrder.PushFront(&propCacheEntry{key: key, val: val})
+	if propCacheOrder.Len() <= propCacheSize {
+		return
+	}
+	oldest := propCacheOrder.Back()
+	propCacheOrder.Remove(oldest)
+	delete(propCacheItems, oldest.Value.(*propCacheEntry).key)
+}
+
+// CachedValue wraps a Value so that repeated property lookups on it are
+// memoized: the key is interned once via InternString and every lookup
+// after the first is served from an LRU of resolved child handles
+// instead of crossing back into the host.
+type CachedValue struct {
+	Value
+}
+
+// Cache wraps v for memoized property access. Cheap to call repeatedly;
+// wrap each Value once per place it's accessed in a hot loop.
+func (v Value) Cache() CachedValue {
+	return CachedValue{Value: v}
+}
+
+// GetObjProp returns the value of the named property, memoizing the
+// result for subsequent calls with the same name on the same parent.
+func (c CachedValue) GetObjProp(name string) Value {
+	return c.GetInternedObjProp(internCached(name))
+}
+
+// GetInternedObjProp returns the value of a property looked up by
+// interned string ID, memoizing the result for subsequent calls with the
+// same ID on the same parent.
+func (c CachedValue) GetInternedObjProp(id uint32) Value {
+	key := propCacheKey{parent: c.raw, id: id}
+	if val, ok := getPropCache(key); ok {
+		return val
+	}
+	val := c.Value.GetInternedObjProp(id)
+	putPropCache(key, val)
+	return val
+}
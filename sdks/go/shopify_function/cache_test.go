@@ -0,0 +1,136 @@
+package shopify_function_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Shopify/shopify-function-wasm-api/sdks/go/internal/hostvalue"
+	sf "github.com/Shopify/shopify-function-wasm-api/sdks/go/shopify_function"
+	"github.com/Shopify/shopify-function-wasm-api/sdks/go/shopifyfunctiontest"
+)
+
+func TestCachedValueGetObjPropMatchesUncached(t *testing.T) {
+	shopifyfunctiontest.Run(t, `{"a":{"b":1}}`, func() {
+		a := sf.InputGet().GetObjProp("a")
+		cached := a.Cache()
+
+		want, ok := a.GetObjProp("b").AsNumber()
+		if !ok {
+			t.Fatal("expected a.b to be a number")
+		}
+		got, ok := cached.GetObjProp("b").AsNumber()
+		if !ok || got != want {
+			t.Fatalf("cached GetObjProp(%q) = %v, %v, want %v, true", "b", got, ok, want)
+		}
+	})
+}
+
+func TestCachedValueGetObjPropRepeatedLookupsAgree(t *testing.T) {
+	shopifyfunctiontest.Run(t, `{"b":1}`, func() {
+		cached := sf.InputGet().Cache()
+
+		first := cached.GetObjProp("b")
+		second := cached.GetObjProp("b")
+		firstVal, _ := first.AsNumber()
+		secondVal, _ := second.AsNumber()
+		if firstVal != secondVal {
+			t.Fatalf("repeated lookups of the same key disagreed: %v != %v", firstVal, secondVal)
+		}
+	})
+}
+
+func TestCachedValueMissingPropIsNull(t *testing.T) {
+	shopifyfunctiontest.Run(t, `{"a":1}`, func() {
+		cached := sf.InputGet().Cache()
+		if !cached.GetObjProp("missing").IsNull() {
+			t.Fatal("expected a lookup of a missing property to be null")
+		}
+	})
+}
+
+func TestSetPropCacheSizeSmallerThanLookupsStillResolves(t *testing.T) {
+	shopifyfunctiontest.Run(t, `{"lines":[{"q":1},{"q":2},{"q":3}]}`, func() {
+		sf.SetPropCacheSize(2)
+		defer sf.SetPropCacheSize(4096)
+
+		lines := sf.InputGet().GetObjProp("lines")
+		for i := uint32(0); i < 3; i++ {
+			got := lines.GetAtIndex(i).Cache().GetObjProp("q")
+			if got.IsNull() {
+				t.Fatalf("line %d: expected q to resolve even with a cache size smaller than the number of lines", i)
+			}
+		}
+	})
+}
+
+func TestSetPropCacheSizeZeroDisablesCaching(t *testing.T) {
+	shopifyfunctiontest.Run(t, `{"b":1}`, func() {
+		sf.SetPropCacheSize(0)
+		defer sf.SetPropCacheSize(4096)
+
+		cached := sf.InputGet().Cache()
+		got, ok := cached.GetObjProp("b").AsNumber()
+		if !ok || got != 1 {
+			t.Fatalf("GetObjProp with caching disabled = %v, %v, want 1, true", got, ok)
+		}
+	})
+}
+
+// syntheticCart returns the JSON for a cart with n lines, each with a
+// quantity and a merchandise ID, matching the shape referenced by the
+// cart-lines example the CachedValue request was written against.
+func syntheticCart(n int) string {
+	var b strings.Builder
+	b.WriteString(`{"cart":{"lines":[`)
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `{"quantity":%d,"merchandise":{"id":"gid://shopify/ProductVariant/%d"}}`, i%5+1, i)
+	}
+	b.WriteString(`]}}`)
+	return b.String()
+}
+
+// BenchmarkGetObjPropUncached and BenchmarkGetObjPropCached read the same
+// property off every line of a synthetic 1000-line cart, the scenario the
+// CachedValue request called out: a loop that touches the same property
+// name on many array elements.
+//
+// The !wasm host double's GetObjProp is a cheap in-process linear scan, not
+// a wasm-boundary crossing with host-side hashing, so these numbers don't
+// show CachedValue's real-world win; they exist to catch regressions in
+// the cache's own bookkeeping overhead (map, LRU list, sync.Map lookup).
+
+func BenchmarkGetObjPropUncached(b *testing.B) {
+	input, err := hostvalue.FromJSON([]byte(syntheticCart(1000)))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sf.ResetTestHost(input)
+		lines := sf.InputGet().GetObjProp("cart").GetObjProp("lines")
+		n, _ := lines.ArrayLen()
+		for j := uint32(0); j < n; j++ {
+			lines.GetAtIndex(j).GetObjProp("quantity")
+		}
+	}
+}
+
+func BenchmarkGetObjPropCached(b *testing.B) {
+	input, err := hostvalue.FromJSON([]byte(syntheticCart(1000)))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sf.ResetTestHost(input)
+		lines := sf.InputGet().GetObjProp("cart").GetObjProp("lines")
+		n, _ := lines.ArrayLen()
+		for j := uint32(0); j < n; j++ {
+			lines.GetAtIndex(j).Cache().GetObjProp("quantity")
+		}
+	}
+}
@@ -0,0 +1,492 @@
+package shopify_function
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrUnsupportedType is returned by Marshal and Unmarshal when they
+// encounter a Go type that has no representation in the Shopify Function
+// value model.
+var ErrUnsupportedType = errors.New("shopify_function: unsupported type")
+
+var internCache sync.Map // map[string]uint32
+
+// internCached interns s on its first call and returns the cached ID on
+// subsequent calls, until resetInternCache clears the cache.
+func internCached(s string) uint32 {
+	if id, ok := internCache.Load(s); ok {
+		return id.(uint32)
+	}
+	id := InternString(s)
+	internCache.Store(s, id)
+	return id
+}
+
+// resetInternCache drops every cached (string -> interned ID) mapping.
+// It exists for the !wasm test harness, which can run many logical
+// invocations in a single process: in production each invocation gets a
+// fresh wasm instantiation, so a process-lifetime cache never observes
+// more than one host intern table.
+func resetInternCache() {
+	internCache = sync.Map{}
+}
+
+// fieldOpts is the parsed form of an `sf:"..."` struct tag.
+type fieldOpts struct {
+	name      string
+	omitempty bool
+	intern    bool
+	skip      bool
+}
+
+func parseFieldOpts(field reflect.StructField) fieldOpts {
+	opts := fieldOpts{name: field.Name}
+	if !field.IsExported() {
+		opts.skip = true
+		return opts
+	}
+	tag, ok := field.Tag.Lookup("sf")
+	if !ok {
+		return opts
+	}
+	parts := strings.Split(tag, ",")
+	if parts[0] == "-" && len(parts) == 1 {
+		opts.skip = true
+		return opts
+	}
+	if parts[0] != "" {
+		opts.name = parts[0]
+	}
+	for _, p := range parts[1:] {
+		switch p {
+		case "omitempty":
+			opts.omitempty = true
+		case "intern":
+			opts.intern = true
+		}
+	}
+	return opts
+}
+
+// Marshal walks v with reflection and writes the equivalent sequence of
+// Output* calls. v (or the value it points to) must be a struct, map,
+// slice, array, string, bool, numeric type, or any of those wrapped in
+// interfaces/pointers; nil values and nil pointers are written as null.
+//
+// Struct fields are encoded using their name unless overridden by an
+// `sf:"name"` tag. The tag also accepts the options "omitempty" (skip the
+// field when it holds its zero value) and "intern" (write the field name,
+// and read it back, via the interned-string fast path).
+//
+// Map keys are written sorted, matching encoding/json, so Marshal of the
+// same map value always produces the same output regardless of Go's
+// randomized map iteration order.
+//
+// v is built into an intermediate tree before anything is written: a
+// value found partway through a struct or slice (an unsupported type, an
+// unsupported map key) fails Marshal without having opened any
+// OutputObject/OutputArray that it couldn't also close, since the host
+// has no way to roll back an Output* call once made.
+func Marshal(v any) error {
+	tree, err := buildValue(reflect.ValueOf(v), fieldOpts{})
+	if err != nil {
+		return err
+	}
+	return flushValue(tree)
+}
+
+// treeObject, treeString, and treeNumber are the intermediate
+// representation buildValue produces and flushValue consumes; they exist
+// solely to defer the decision of which Output* call to make (plain vs.
+// interned string, I32 vs. F64) until the whole value is known-valid.
+type treeObject struct {
+	keys []treeString
+	vals []any
+}
+
+type treeString struct {
+	value    string
+	interned bool
+	id       uint32
+}
+
+type treeNumber struct {
+	value float64
+	i32   bool
+}
+
+func buildValue(rv reflect.Value, opts fieldOpts) (any, error) {
+	if !rv.IsValid() {
+		return nil, nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		return buildValue(rv.Elem(), opts)
+
+	case reflect.Bool:
+		return rv.Bool(), nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return buildNumber(float64(rv.Int())), nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return buildNumber(float64(rv.Uint())), nil
+
+	case reflect.Float32, reflect.Float64:
+		return buildNumber(rv.Float()), nil
+
+	case reflect.String:
+		return buildString(rv.String(), opts.intern), nil
+
+	case reflect.Slice, reflect.Array:
+		return buildSlice(rv)
+
+	case reflect.Map:
+		return buildMap(rv)
+
+	case reflect.Struct:
+		return buildStruct(rv)
+
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, rv.Kind())
+	}
+}
+
+// buildNumber applies the integer-vs-float heuristic: whole numbers that
+// fit in an int32 are written with OutputI32, everything else with
+// OutputF64.
+func buildNumber(num float64) treeNumber {
+	if num == float64(int32(num)) {
+		return treeNumber{value: num, i32: true}
+	}
+	return treeNumber{value: num}
+}
+
+func buildString(s string, intern bool) treeString {
+	if intern {
+		return treeString{value: s, interned: true, id: internCached(s)}
+	}
+	return treeString{value: s}
+}
+
+func buildSlice(rv reflect.Value) (any, error) {
+	if rv.Kind() == reflect.Slice && rv.IsNil() {
+		return nil, nil
+	}
+	n := rv.Len()
+	vals := make([]any, n)
+	for i := 0; i < n; i++ {
+		v, err := buildValue(rv.Index(i), fieldOpts{})
+		if err != nil {
+			return nil, err
+		}
+		vals[i] = v
+	}
+	return vals, nil
+}
+
+func buildMap(rv reflect.Value) (any, error) {
+	if rv.IsNil() {
+		return nil, nil
+	}
+	if rv.Type().Key().Kind() != reflect.String {
+		return nil, fmt.Errorf("%w: map key %s", ErrUnsupportedType, rv.Type().Key())
+	}
+	keys := rv.MapKeys()
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+	obj := &treeObject{keys: make([]treeString, len(keys)), vals: make([]any, len(keys))}
+	for i, key := range keys {
+		obj.keys[i] = treeString{value: key.String()}
+		v, err := buildValue(rv.MapIndex(key), fieldOpts{})
+		if err != nil {
+			return nil, err
+		}
+		obj.vals[i] = v
+	}
+	return obj, nil
+}
+
+func buildStruct(rv reflect.Value) (any, error) {
+	t := rv.Type()
+	obj := &treeObject{}
+	for i := 0; i < t.NumField(); i++ {
+		opts := parseFieldOpts(t.Field(i))
+		if opts.skip {
+			continue
+		}
+		fv := rv.Field(i)
+		if opts.omitempty && fv.IsZero() {
+			continue
+		}
+		v, err := buildValue(fv, opts)
+		if err != nil {
+			return nil, err
+		}
+		obj.keys = append(obj.keys, buildString(opts.name, opts.intern))
+		obj.vals = append(obj.vals, v)
+	}
+	return obj, nil
+}
+
+// flushValue writes a tree built by buildValue via the Output* family.
+// By the time it's called the whole tree is known to be representable,
+// so every OutputObject/OutputArray it opens is guaranteed a matching
+// OutputFinish{Object,Array} (barring a write error from the host
+// itself, which is unrecoverable in either design).
+func flushValue(v any) error {
+	switch val := v.(type) {
+	case nil:
+		return OutputNull()
+	case bool:
+		return OutputBool(val)
+	case treeNumber:
+		if val.i32 {
+			return OutputI32(int32(val.value))
+		}
+		return OutputF64(val.value)
+	case treeString:
+		return flushString(val)
+	case []any:
+		return flushSlice(val)
+	case *treeObject:
+		return flushObject(val)
+	default:
+		return fmt.Errorf("%w: internal tree node %T", ErrUnsupportedType, v)
+	}
+}
+
+func flushString(s treeString) error {
+	if s.interned {
+		return OutputInternedString(s.id)
+	}
+	return OutputString(s.value)
+}
+
+func flushSlice(vals []any) error {
+	if err := OutputArray(uint32(len(vals))); err != nil {
+		return err
+	}
+	for _, v := range vals {
+		if err := flushValue(v); err != nil {
+			return err
+		}
+	}
+	return OutputFinishArray()
+}
+
+func flushObject(obj *treeObject) error {
+	if err := OutputObject(uint32(len(obj.keys))); err != nil {
+		return err
+	}
+	for i, key := range obj.keys {
+		if err := flushString(key); err != nil {
+			return err
+		}
+		if err := flushValue(obj.vals[i]); err != nil {
+			return err
+		}
+	}
+	return OutputFinishObject()
+}
+
+// Unmarshal walks val via Tag, ObjLen, GetObjProp, GetAtIndex, and friends,
+// populating out, which must be a non-nil pointer. The same `sf` struct
+// tags recognized by Marshal control field names and interned-string
+// access; "omitempty" has no effect on decoding.
+func Unmarshal(val Value, out any) error {
+	rv := reflect.ValueOf(out)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("%w: Unmarshal requires a non-nil pointer", ErrUnsupportedType)
+	}
+	return unmarshalValue(val, rv.Elem(), fieldOpts{})
+}
+
+func unmarshalValue(val Value, rv reflect.Value, opts fieldOpts) error {
+	if rv.Kind() == reflect.Pointer {
+		if val.IsNull() {
+			rv.Set(reflect.Zero(rv.Type()))
+			return nil
+		}
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return unmarshalValue(val, rv.Elem(), opts)
+	}
+
+	if rv.Kind() == reflect.Interface && rv.NumMethod() == 0 {
+		decoded, err := unmarshalAny(val)
+		if err != nil {
+			return err
+		}
+		if decoded != nil {
+			rv.Set(reflect.ValueOf(decoded))
+		}
+		return nil
+	}
+
+	switch val.Tag() {
+	case TagNull:
+		rv.Set(reflect.Zero(rv.Type()))
+		return nil
+
+	case TagBool:
+		b, _ := val.AsBool()
+		if rv.Kind() != reflect.Bool {
+			return fmt.Errorf("%w: cannot decode bool into %s", ErrUnsupportedType, rv.Type())
+		}
+		rv.SetBool(b)
+		return nil
+
+	case TagNumber:
+		num, _ := val.AsNumber()
+		return unmarshalNumber(num, rv)
+
+	case TagString:
+		s := val.ReadStringAlloc()
+		if rv.Kind() != reflect.String {
+			return fmt.Errorf("%w: cannot decode string into %s", ErrUnsupportedType, rv.Type())
+		}
+		rv.SetString(s)
+		return nil
+
+	case TagArray:
+		return unmarshalArray(val, rv)
+
+	case TagObject:
+		return unmarshalObject(val, rv)
+
+	default:
+		return fmt.Errorf("%w: value tag %d", ErrUnsupportedType, val.Tag())
+	}
+}
+
+func unmarshalNumber(num float64, rv reflect.Value) error {
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		rv.SetInt(int64(num))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		rv.SetUint(uint64(num))
+	case reflect.Float32, reflect.Float64:
+		rv.SetFloat(num)
+	default:
+		return fmt.Errorf("%w: cannot decode number into %s", ErrUnsupportedType, rv.Type())
+	}
+	return nil
+}
+
+func unmarshalArray(val Value, rv reflect.Value) error {
+	n, ok := val.ArrayLen()
+	if !ok {
+		return fmt.Errorf("%w: expected array", ErrUnsupportedType)
+	}
+	if rv.Kind() != reflect.Slice {
+		return fmt.Errorf("%w: cannot decode array into %s", ErrUnsupportedType, rv.Type())
+	}
+	out := reflect.MakeSlice(rv.Type(), int(n), int(n))
+	for i := uint32(0); i < n; i++ {
+		if err := unmarshalValue(val.GetAtIndex(i), out.Index(int(i)), fieldOpts{}); err != nil {
+			return err
+		}
+	}
+	rv.Set(out)
+	return nil
+}
+
+func unmarshalObject(val Value, rv reflect.Value) error {
+	switch rv.Kind() {
+	case reflect.Struct:
+		return unmarshalStruct(val, rv)
+	case reflect.Map:
+		return unmarshalMap(val, rv)
+	default:
+		return fmt.Errorf("%w: cannot decode object into %s", ErrUnsupportedType, rv.Type())
+	}
+}
+
+func unmarshalStruct(val Value, rv reflect.Value) error {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		opts := parseFieldOpts(t.Field(i))
+		if opts.skip {
+			continue
+		}
+		var child Value
+		if opts.intern {
+			child = val.GetInternedObjProp(internCached(opts.name))
+		} else {
+			child = val.GetObjProp(opts.name)
+		}
+		if err := unmarshalValue(child, rv.Field(i), opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func unmarshalMap(val Value, rv reflect.Value) error {
+	if rv.Type().Key().Kind() != reflect.String {
+		return fmt.Errorf("%w: map key %s", ErrUnsupportedType, rv.Type().Key())
+	}
+	n, _ := val.ObjLen()
+	out := reflect.MakeMapWithSize(rv.Type(), int(n))
+	elemType := rv.Type().Elem()
+	for i := uint32(0); i < n; i++ {
+		key := val.GetObjKeyAtIndex(i).ReadStringAlloc()
+		elem := reflect.New(elemType).Elem()
+		if err := unmarshalValue(val.GetAtIndex(i), elem, fieldOpts{}); err != nil {
+			return err
+		}
+		out.SetMapIndex(reflect.ValueOf(key).Convert(rv.Type().Key()), elem)
+	}
+	rv.Set(out)
+	return nil
+}
+
+func unmarshalAny(val Value) (any, error) {
+	switch val.Tag() {
+	case TagNull:
+		return nil, nil
+	case TagBool:
+		b, _ := val.AsBool()
+		return b, nil
+	case TagNumber:
+		num, _ := val.AsNumber()
+		return num, nil
+	case TagString:
+		return val.ReadStringAlloc(), nil
+	case TagArray:
+		n, _ := val.ArrayLen()
+		out := make([]any, n)
+		for i := uint32(0); i < n; i++ {
+			elem, err := unmarshalAny(val.GetAtIndex(i))
+			if err != nil {
+				return nil, err
+			}
+			out[i] = elem
+		}
+		return out, nil
+	case TagObject:
+		n, _ := val.ObjLen()
+		out := make(map[string]any, n)
+		for i := uint32(0); i < n; i++ {
+			key := val.GetObjKeyAtIndex(i).ReadStringAlloc()
+			elem, err := unmarshalAny(val.GetAtIndex(i))
+			if err != nil {
+				return nil, err
+			}
+			out[key] = elem
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("%w: value tag %d", ErrUnsupportedType, val.Tag())
+	}
+}
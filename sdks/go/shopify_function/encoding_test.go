@@ -0,0 +1,165 @@
+package shopify_function_test
+
+import (
+	"testing"
+
+	sf "github.com/Shopify/shopify-function-wasm-api/sdks/go/shopify_function"
+	"github.com/Shopify/shopify-function-wasm-api/sdks/go/shopifyfunctiontest"
+)
+
+func marshalJSON(t *testing.T, v any) string {
+	t.Helper()
+	out, _ := shopifyfunctiontest.Run(t, "null", func() {
+		if err := sf.Marshal(v); err != nil {
+			t.Fatalf("Marshal: %v", err)
+		}
+	})
+	return out
+}
+
+func TestMarshalPrimitives(t *testing.T) {
+	cases := []struct {
+		name string
+		in   any
+		want string
+	}{
+		{"nil", nil, "null"},
+		{"bool true", true, "true"},
+		{"bool false", false, "false"},
+		{"whole float as int", 3.0, "3"},
+		{"non-whole float", 3.5, "3.5"},
+		{"int", 7, "7"},
+		{"string", "hi", `"hi"`},
+		{"nil slice", []string(nil), "null"},
+		{"empty slice", []string{}, "[]"},
+		{"slice", []int{1, 2, 3}, "[1,2,3]"},
+		{"nil map", map[string]int(nil), "null"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := marshalJSON(t, tc.in); got != tc.want {
+				t.Errorf("Marshal(%#v) = %s, want %s", tc.in, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMarshalStructTags(t *testing.T) {
+	type inner struct {
+		Keep    string `sf:"keep"`
+		Skipped string `sf:"-"`
+		Omitted string `sf:"omitted,omitempty"`
+		unexp   string
+	}
+
+	in := inner{Keep: "k", unexp: "hidden"}
+	got := marshalJSON(t, in)
+	want := `{"keep":"k"}`
+	if got != want {
+		t.Errorf("Marshal(%#v) = %s, want %s", in, got, want)
+	}
+}
+
+func TestMarshalInternedField(t *testing.T) {
+	type withIntern struct {
+		Name string `sf:"name,intern"`
+	}
+	got := marshalJSON(t, withIntern{Name: "shopify"})
+	want := `{"name":"shopify"}`
+	if got != want {
+		t.Errorf("Marshal with intern tag = %s, want %s", got, want)
+	}
+}
+
+func TestMarshalMap(t *testing.T) {
+	got := marshalJSON(t, map[string]int{"a": 1})
+	want := `{"a":1}`
+	if got != want {
+		t.Errorf("Marshal(map) = %s, want %s", got, want)
+	}
+}
+
+func TestMarshalMapKeysAreSorted(t *testing.T) {
+	in := map[string]int{"z": 1, "a": 2, "m": 3}
+	want := `{"a":2,"m":3,"z":1}`
+	for i := 0; i < 10; i++ {
+		if got := marshalJSON(t, in); got != want {
+			t.Fatalf("Marshal(map) = %s, want %s", got, want)
+		}
+	}
+}
+
+func TestMarshalUnsupportedTypeLeavesNoOutput(t *testing.T) {
+	type bad struct {
+		C complex128 `sf:"c"`
+	}
+
+	var marshalErr error
+	shopifyfunctiontest.Run(t, "null", func() {
+		marshalErr = sf.Marshal(bad{C: 1 + 2i})
+	})
+
+	if marshalErr == nil {
+		t.Fatal("expected Marshal to fail on an unsupported field type")
+	}
+	if out := shopifyfunctiontest.OutputTree(); out != nil {
+		t.Fatalf("expected no output to have been written on error, got %#v", out)
+	}
+}
+
+func TestUnmarshalNestedStruct(t *testing.T) {
+	type line struct {
+		Quantity float64 `sf:"quantity"`
+	}
+	type cart struct {
+		Lines []line `sf:"lines"`
+	}
+	type input struct {
+		Cart cart `sf:"cart"`
+	}
+
+	var decoded input
+	shopifyfunctiontest.Run(t, `{"cart":{"lines":[{"quantity":2},{"quantity":1}]}}`, func() {
+		if err := sf.Unmarshal(sf.InputGet(), &decoded); err != nil {
+			t.Fatalf("Unmarshal: %v", err)
+		}
+	})
+
+	if len(decoded.Cart.Lines) != 2 || decoded.Cart.Lines[0].Quantity != 2 || decoded.Cart.Lines[1].Quantity != 1 {
+		t.Fatalf("unexpected decode result: %#v", decoded)
+	}
+}
+
+func TestUnmarshalIntoAny(t *testing.T) {
+	var decoded any
+	shopifyfunctiontest.Run(t, `{"a":[1,2,"three"],"b":null}`, func() {
+		if err := sf.Unmarshal(sf.InputGet(), &decoded); err != nil {
+			t.Fatalf("Unmarshal: %v", err)
+		}
+	})
+
+	m, ok := decoded.(map[string]any)
+	if !ok {
+		t.Fatalf("expected map[string]any, got %T", decoded)
+	}
+	arr, ok := m["a"].([]any)
+	if !ok || len(arr) != 3 {
+		t.Fatalf("unexpected value for \"a\": %#v", m["a"])
+	}
+}
+
+func TestUnmarshalInternedField(t *testing.T) {
+	type withIntern struct {
+		Token string `sf:"token,intern"`
+	}
+
+	var decoded withIntern
+	shopifyfunctiontest.Run(t, `{"token":"shopify"}`, func() {
+		if err := sf.Unmarshal(sf.InputGet(), &decoded); err != nil {
+			t.Fatalf("Unmarshal: %v", err)
+		}
+	})
+	if decoded.Token != "shopify" {
+		t.Fatalf("got %q, want %q", decoded.Token, "shopify")
+	}
+}
@@ -0,0 +1,86 @@
+package shopify_function
+
+import (
+	"context"
+	"errors"
+)
+
+// TargetedError is an error that also carries the JSONPath target of the
+// function error it should produce when returned from a Handle'd
+// function. Any other error targets the root of the input ($).
+type TargetedError struct {
+	err    error
+	target Path
+}
+
+// NewTargetedError wraps err with the JSONPath target for the function
+// error it should produce.
+func NewTargetedError(err error, target Path) *TargetedError {
+	return &TargetedError{err: err, target: target}
+}
+
+func (e *TargetedError) Error() string { return e.err.Error() }
+func (e *TargetedError) Unwrap() error { return e.err }
+
+var registeredHandler func() error
+
+// Handle registers fn as the program's entry point. Run, called from
+// main, decodes the input into In via Unmarshal, invokes fn, and encodes
+// the result via Marshal. If any of those three steps fails - a bad
+// input, fn itself, or an Out value Marshal can't encode - Run writes the
+// standard {"errors": [...]} shape instead, targeting the error at the
+// path carried by a TargetedError or at the input root otherwise.
+func Handle[In, Out any](fn func(context.Context, In) (Out, error)) {
+	registeredHandler = func() error {
+		var in In
+		if err := Unmarshal(InputGet(), &in); err != nil {
+			return writeHandlerError(err)
+		}
+		out, err := fn(context.Background(), in)
+		if err != nil {
+			return writeHandlerError(err)
+		}
+		if err := Marshal(out); err != nil {
+			return writeHandlerError(err)
+		}
+		return nil
+	}
+}
+
+// MustRegister is Handle under the name some callers expect for
+// registration APIs; it has identical behavior.
+func MustRegister[In, Out any](fn func(context.Context, In) (Out, error)) {
+	Handle(fn)
+}
+
+// InternKeys interns each of keys immediately, so field names used by the
+// "intern" option of an `sf` struct tag are already registered with the
+// host before Run decodes the first input. Call it from an init function
+// or at the top of main, before Run.
+func InternKeys(keys ...string) {
+	for _, key := range keys {
+		internCached(key)
+	}
+}
+
+// Run invokes the handler registered via Handle or MustRegister. Call it
+// once from main, after any InternKeys calls. The handler itself already
+// writes a {"errors": [...]} output on failure; an error returned from it
+// means even that write failed, so Run falls back to logging it.
+func Run() {
+	if registeredHandler == nil {
+		panic("shopify_function: Run called without a handler registered via Handle")
+	}
+	if err := registeredHandler(); err != nil {
+		Log(err.Error())
+	}
+}
+
+func writeHandlerError(err error) error {
+	target := Root()
+	var targeted *TargetedError
+	if errors.As(err, &targeted) {
+		target = targeted.target
+	}
+	return NewErrorsBuilder().Add(err.Error(), target).Flush()
+}
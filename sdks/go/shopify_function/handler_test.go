@@ -0,0 +1,71 @@
+package shopify_function_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	sf "github.com/Shopify/shopify-function-wasm-api/sdks/go/shopify_function"
+	"github.com/Shopify/shopify-function-wasm-api/sdks/go/shopifyfunctiontest"
+)
+
+func runHandled[In, Out any](t *testing.T, inputJSON string, fn func(context.Context, In) (Out, error)) string {
+	t.Helper()
+	sf.Handle(fn)
+	out, _ := shopifyfunctiontest.Run(t, inputJSON, sf.Run)
+	return out
+}
+
+func TestHandleWritesOutputOnSuccess(t *testing.T) {
+	type in struct {
+		N float64 `sf:"n"`
+	}
+	type out struct {
+		Doubled float64 `sf:"doubled"`
+	}
+	got := runHandled(t, `{"n":2}`, func(_ context.Context, v in) (out, error) {
+		return out{Doubled: v.N * 2}, nil
+	})
+	if want := `{"doubled":4}`; got != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+}
+
+func TestHandleWritesErrorsOnDecodeFailure(t *testing.T) {
+	type in struct {
+		N complex128 `sf:"n"`
+	}
+	type out struct{}
+	got := runHandled(t, `{"n":2}`, func(_ context.Context, v in) (out, error) {
+		t.Fatal("fn should not be called when decoding the input fails")
+		return out{}, nil
+	})
+	if got == "null" || got == "" {
+		t.Fatalf("expected a structured {errors:[...]} output on decode failure, got %s", got)
+	}
+}
+
+func TestHandleWritesErrorsOnFnFailure(t *testing.T) {
+	type in struct{}
+	type out struct{}
+	wantErr := errors.New("boom")
+	got := runHandled(t, "null", func(_ context.Context, v in) (out, error) {
+		return out{}, wantErr
+	})
+	if got == "null" || got == "" {
+		t.Fatalf("expected a structured {errors:[...]} output when fn errors, got %s", got)
+	}
+}
+
+func TestHandleWritesErrorsOnEncodeFailure(t *testing.T) {
+	type in struct{}
+	type out struct {
+		C complex128 `sf:"c"`
+	}
+	got := runHandled(t, "null", func(_ context.Context, v in) (out, error) {
+		return out{C: 1 + 2i}, nil
+	})
+	if got == "null" || got == "" {
+		t.Fatalf("expected a structured {errors:[...]} output when the return value can't be encoded, got %s", got)
+	}
+}
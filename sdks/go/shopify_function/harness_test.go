@@ -0,0 +1,105 @@
+package shopify_function_test
+
+import (
+	"strings"
+	"testing"
+
+	sf "github.com/Shopify/shopify-function-wasm-api/sdks/go/shopify_function"
+	"github.com/Shopify/shopify-function-wasm-api/sdks/go/shopifyfunctiontest"
+)
+
+func TestOutputFinishObjectWithoutMatchingOpenIsWriteError(t *testing.T) {
+	var err error
+	shopifyfunctiontest.Run(t, "null", func() {
+		err = sf.OutputFinishObject()
+	})
+	if err != sf.ErrWrite {
+		t.Fatalf("got %v, want %v", err, sf.ErrWrite)
+	}
+}
+
+func TestOutputFinishArrayAfterOpenObjectIsWriteError(t *testing.T) {
+	var err error
+	shopifyfunctiontest.Run(t, "null", func() {
+		if e := sf.OutputObject(0); e != nil {
+			t.Fatalf("OutputObject: %v", e)
+		}
+		err = sf.OutputFinishArray()
+	})
+	if err != sf.ErrWrite {
+		t.Fatalf("got %v, want %v", err, sf.ErrWrite)
+	}
+}
+
+func TestLongStringOverflowsInlineLength(t *testing.T) {
+	long := strings.Repeat("x", 20000)
+	var got string
+	shopifyfunctiontest.Run(t, `{"s":"`+long+`"}`, func() {
+		s := sf.InputGet().GetObjProp("s")
+		buf := make([]byte, s.StringLen())
+		s.ReadString(buf)
+		got = string(buf)
+	})
+	if got != long {
+		t.Fatalf("got length %d, want %d", len(got), len(long))
+	}
+}
+
+func TestInternedStringRoundTrip(t *testing.T) {
+	out, _ := shopifyfunctiontest.Run(t, "null", func() {
+		id := sf.InternString("reused")
+		sf.OutputArray(2)
+		sf.OutputInternedString(id)
+		sf.OutputInternedString(id)
+		sf.OutputFinishArray()
+	})
+	want := `["reused","reused"]`
+	if out != want {
+		t.Fatalf("got %s, want %s", out, want)
+	}
+}
+
+func TestInternCacheDoesNotLeakAcrossInvocations(t *testing.T) {
+	type withIntern struct {
+		Dup string `sf:"dup,intern"`
+	}
+
+	shopifyfunctiontest.Run(t, "null", func() {
+		if err := sf.Marshal(withIntern{Dup: "first"}); err != nil {
+			t.Fatalf("Marshal: %v", err)
+		}
+	})
+
+	var decoded withIntern
+	shopifyfunctiontest.Run(t, `{"dup":"second"}`, func() {
+		if err := sf.Unmarshal(sf.InputGet(), &decoded); err != nil {
+			t.Fatalf("Unmarshal: %v", err)
+		}
+	})
+	if decoded.Dup != "second" {
+		t.Fatalf("got %q, want %q: an ID cached against the previous invocation's host leaked into this one", decoded.Dup, "second")
+	}
+}
+
+func TestObjectKeyIteration(t *testing.T) {
+	var keys []string
+	shopifyfunctiontest.Run(t, `{"a":1,"b":2,"c":3}`, func() {
+		obj := sf.InputGet()
+		n, ok := obj.ObjLen()
+		if !ok {
+			t.Fatal("expected an object")
+		}
+		for i := uint32(0); i < n; i++ {
+			keys = append(keys, obj.GetObjKeyAtIndex(i).ReadStringAlloc())
+		}
+	})
+	want := []string{"a", "b", "c"}
+	if len(keys) != len(want) {
+		t.Fatalf("got %v, want %v", keys, want)
+	}
+	for i, k := range want {
+		if keys[i] != k {
+			t.Fatalf("got %v, want %v", keys, want)
+		}
+	}
+}
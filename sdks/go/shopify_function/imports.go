@@ -1,3 +1,5 @@
+//go:build wasm
+
 package shopify_function
 
 // WASM import declarations for the Shopify Function API.
@@ -0,0 +1,132 @@
+//go:build !wasm
+
+package shopify_function
+
+import (
+	"unsafe"
+
+	"github.com/Shopify/shopify-function-wasm-api/sdks/go/internal/hostvalue"
+)
+
+// testHost backs every import below when the package is built for any
+// target other than wasm. The real imports in imports.go are likewise
+// package-level functions with no receiver and handle a single invocation
+// at a time, so one shared instance mirrors their behavior.
+var testHost = hostvalue.NewState()
+
+// ResetTestHost clears any state left over from a previous invocation and
+// seeds the host with input as the new root input value. It is used by
+// shopifyfunctiontest and is only available outside the wasm build.
+//
+// It also clears the process-wide intern cache shared by Marshal,
+// Unmarshal, Logger, CachedValue, and InternKeys: in production each
+// invocation gets a fresh wasm instantiation, so that cache never
+// outlives one host intern table, but a test binary can run many
+// invocations in the same process and must not let IDs from a previous
+// one's (now-reset) intern table leak into this one.
+func ResetTestHost(input any) {
+	testHost.Reset(input)
+	resetInternCache()
+}
+
+// TestHostOutput returns the tree assembled by Output* calls since the
+// last ResetTestHost.
+func TestHostOutput() any {
+	return testHost.Output()
+}
+
+// TestHostLogs returns the messages recorded via Log since the last
+// ResetTestHost.
+func TestHostLogs() []string {
+	return testHost.Logs()
+}
+
+func inputGet() int64 {
+	return testHost.Root()
+}
+
+func inputGetValLen(scope int64) uint32 {
+	return testHost.ValLen(scope)
+}
+
+func inputReadUtf8Str(src uint32, out *byte, length uint32) {
+	testHost.ReadUTF8(src, unsafe.Slice(out, length))
+}
+
+func inputGetObjProp(scope int64, ptr *byte, length uint32) int64 {
+	return testHost.ObjProp(scope, unsafe.String(ptr, length))
+}
+
+func inputGetInternedObjProp(scope int64, id uint32) int64 {
+	return testHost.InternedObjProp(scope, id)
+}
+
+func inputGetAtIndex(scope int64, index uint32) int64 {
+	return testHost.AtIndex(scope, index)
+}
+
+func inputGetObjKeyAtIndex(scope int64, index uint32) int64 {
+	return testHost.KeyAtIndex(scope, index)
+}
+
+func outputNewBool(value uint32) int32 {
+	testHost.OutputBool(value != 0)
+	return 0
+}
+
+func outputNewNull() int32 {
+	testHost.OutputNull()
+	return 0
+}
+
+func outputNewI32(value int32) int32 {
+	testHost.OutputI32(value)
+	return 0
+}
+
+func outputNewF64(value float64) int32 {
+	testHost.OutputF64(value)
+	return 0
+}
+
+func outputNewUtf8Str(ptr *byte, length uint32) int32 {
+	testHost.OutputString(unsafe.String(ptr, length))
+	return 0
+}
+
+func outputNewInternedUtf8Str(id uint32) int32 {
+	testHost.OutputInternedString(id)
+	return 0
+}
+
+func outputNewObject(length uint32) int32 {
+	testHost.OutputNewObject(length)
+	return 0
+}
+
+func outputFinishObject() int32 {
+	if testHost.OutputFinishObject() {
+		return 0
+	}
+	return 1
+}
+
+func outputNewArray(length uint32) int32 {
+	testHost.OutputNewArray(length)
+	return 0
+}
+
+func outputFinishArray() int32 {
+	if testHost.OutputFinishArray() {
+		return 0
+	}
+	return 1
+}
+
+func internUtf8Str(ptr *byte, length uint32) uint32 {
+	return testHost.Intern(unsafe.String(ptr, length))
+}
+
+func logNewUtf8Str(ptr *byte, length uint32) {
+	testHost.Log(unsafe.String(ptr, length))
+}
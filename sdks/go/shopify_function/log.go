@@ -0,0 +1,201 @@
+package shopify_function
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Level identifies the severity of a log line written through Logger.
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// String returns the lowercase name of the level, as written by the
+// default encoder.
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// Field is a single key/value pair attached to a log line, either via
+// Logger.With or passed directly to a level method.
+type Field struct {
+	Key   string
+	Value any
+}
+
+// F is a shorthand for constructing a Field.
+func F(key string, value any) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Encoder renders a level, message, and accumulated fields into the
+// single line of text that's ultimately passed to Log.
+type Encoder interface {
+	Encode(level Level, msg string, fields []Field) string
+}
+
+// jsonEncoder is the default Encoder: a single-line JSON object with
+// "level", "msg", and the fields in accumulation order.
+type jsonEncoder struct{}
+
+func (jsonEncoder) Encode(level Level, msg string, fields []Field) string {
+	var b strings.Builder
+	b.WriteByte('{')
+	writeJSONKey(&b, "level")
+	writeJSONString(&b, level.String())
+	b.WriteByte(',')
+	writeJSONKey(&b, "msg")
+	writeJSONString(&b, msg)
+	for _, f := range fields {
+		b.WriteByte(',')
+		writeJSONKey(&b, f.Key)
+		writeJSONAny(&b, f.Value)
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+func writeJSONKey(b *strings.Builder, key string) {
+	writeJSONString(b, key)
+	b.WriteByte(':')
+}
+
+func writeJSONString(b *strings.Builder, s string) {
+	buf, err := json.Marshal(s)
+	if err != nil {
+		b.WriteString(`""`)
+		return
+	}
+	b.Write(buf)
+}
+
+// writeJSONAny encodes v as JSON. error and fmt.Stringer values are
+// rendered via Error()/String() rather than json.Marshal: most error and
+// Stringer implementations are structs with unexported fields, which
+// json.Marshal happily (and uselessly) encodes as "{}" instead of
+// failing, so checking afterward would never catch them.
+func writeJSONAny(b *strings.Builder, v any) {
+	if s, ok := v.(interface{ Error() string }); ok {
+		writeJSONString(b, s.Error())
+		return
+	}
+	if s, ok := v.(interface{ String() string }); ok {
+		writeJSONString(b, s.String())
+		return
+	}
+	buf, err := json.Marshal(v)
+	if err != nil {
+		writeJSONString(b, "<unencodable>")
+		return
+	}
+	b.Write(buf)
+}
+
+// Logger writes structured log lines through Log. Use With to accumulate
+// context fields that are attached to every subsequent line.
+type Logger struct {
+	encoder Encoder
+	fields  []Field
+}
+
+// NewLogger returns a Logger with the default single-line JSON encoder
+// and no accumulated fields.
+func NewLogger() *Logger {
+	return &Logger{encoder: jsonEncoder{}}
+}
+
+// WithEncoder returns a copy of the logger that renders lines with enc
+// instead of the default JSON encoder.
+func (l *Logger) WithEncoder(enc Encoder) *Logger {
+	return &Logger{encoder: enc, fields: l.fields}
+}
+
+// With returns a copy of the logger with key/value added to the fields
+// attached to every line it writes, for accumulating context (e.g. a
+// request ID) at the top of a function and having it show up on every
+// line logged afterward. key is interned with the host through the
+// shared intern cache, so each distinct key is interned only once.
+func (l *Logger) With(key string, value any) *Logger {
+	internCached(key)
+	fields := make([]Field, len(l.fields), len(l.fields)+1)
+	copy(fields, l.fields)
+	fields = append(fields, Field{Key: key, Value: value})
+	return &Logger{encoder: l.encoder, fields: fields}
+}
+
+func (l *Logger) log(level Level, msg string, fields []Field) {
+	all := make([]Field, 0, len(l.fields)+len(fields))
+	all = append(all, l.fields...)
+	all = append(all, fields...)
+	Log(l.encoder.Encode(level, msg, all))
+}
+
+// Debug writes a debug-level log line.
+func (l *Logger) Debug(msg string, fields ...Field) {
+	l.log(LevelDebug, msg, fields)
+}
+
+// Info writes an info-level log line.
+func (l *Logger) Info(msg string, fields ...Field) {
+	l.log(LevelInfo, msg, fields)
+}
+
+// Warn writes a warn-level log line.
+func (l *Logger) Warn(msg string, fields ...Field) {
+	l.log(LevelWarn, msg, fields)
+}
+
+// Error writes an error-level log line.
+func (l *Logger) Error(msg string, fields ...Field) {
+	l.log(LevelError, msg, fields)
+}
+
+var defaultLogger = NewLogger()
+
+// SetDefault replaces the logger used by the package-level Debug, Info,
+// Warn, and Error functions.
+func SetDefault(l *Logger) {
+	defaultLogger = l
+}
+
+// Default returns the logger used by the package-level Debug, Info, Warn,
+// and Error functions.
+func Default() *Logger {
+	return defaultLogger
+}
+
+// Debug writes a debug-level log line with the default logger.
+func Debug(msg string, fields ...Field) {
+	defaultLogger.Debug(msg, fields...)
+}
+
+// Info writes an info-level log line with the default logger.
+func Info(msg string, fields ...Field) {
+	defaultLogger.Info(msg, fields...)
+}
+
+// Warn writes a warn-level log line with the default logger.
+func Warn(msg string, fields ...Field) {
+	defaultLogger.Warn(msg, fields...)
+}
+
+// Error writes an error-level log line with the default logger.
+func Error(msg string, fields ...Field) {
+	defaultLogger.Error(msg, fields...)
+}
@@ -0,0 +1,142 @@
+package shopify_function_test
+
+import (
+	"errors"
+	"testing"
+
+	sf "github.com/Shopify/shopify-function-wasm-api/sdks/go/shopify_function"
+	"github.com/Shopify/shopify-function-wasm-api/sdks/go/shopifyfunctiontest"
+)
+
+func TestLoggerLevelsProduceExpectedJSON(t *testing.T) {
+	cases := []struct {
+		name string
+		log  func(l *sf.Logger, msg string)
+		want string
+	}{
+		{"debug", func(l *sf.Logger, msg string) { l.Debug(msg) }, `{"level":"debug","msg":"hi"}`},
+		{"info", func(l *sf.Logger, msg string) { l.Info(msg) }, `{"level":"info","msg":"hi"}`},
+		{"warn", func(l *sf.Logger, msg string) { l.Warn(msg) }, `{"level":"warn","msg":"hi"}`},
+		{"error", func(l *sf.Logger, msg string) { l.Error(msg) }, `{"level":"error","msg":"hi"}`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var logs []string
+			shopifyfunctiontest.Run(t, "null", func() {
+				tc.log(sf.NewLogger(), "hi")
+				logs = sf.TestHostLogs()
+			})
+			if len(logs) != 1 || logs[0] != tc.want {
+				t.Fatalf("got %v, want [%s]", logs, tc.want)
+			}
+		})
+	}
+}
+
+func TestLoggerInfoWithFields(t *testing.T) {
+	var logs []string
+	shopifyfunctiontest.Run(t, "null", func() {
+		sf.NewLogger().Info("hi", sf.F("count", 3))
+		logs = sf.TestHostLogs()
+	})
+	want := `{"level":"info","msg":"hi","count":3}`
+	if len(logs) != 1 || logs[0] != want {
+		t.Fatalf("got %v, want [%s]", logs, want)
+	}
+}
+
+func TestLoggerWithAccumulatesAndOrdersFields(t *testing.T) {
+	var logs []string
+	shopifyfunctiontest.Run(t, "null", func() {
+		l := sf.NewLogger().With("request_id", "abc123").With("shop", "acme")
+		l.Info("start")
+		l.Warn("retry", sf.F("attempt", 2))
+		logs = sf.TestHostLogs()
+	})
+	wantStart := `{"level":"info","msg":"start","request_id":"abc123","shop":"acme"}`
+	wantRetry := `{"level":"warn","msg":"retry","request_id":"abc123","shop":"acme","attempt":2}`
+	if len(logs) != 2 || logs[0] != wantStart || logs[1] != wantRetry {
+		t.Fatalf("got %v, want [%s %s]", logs, wantStart, wantRetry)
+	}
+}
+
+func TestLoggerWithReturnsIndependentCopy(t *testing.T) {
+	var logs []string
+	shopifyfunctiontest.Run(t, "null", func() {
+		base := sf.NewLogger().With("a", 1)
+		_ = base.With("b", 2)
+		base.Info("base only")
+		logs = sf.TestHostLogs()
+	})
+	want := `{"level":"info","msg":"base only","a":1}`
+	if len(logs) != 1 || logs[0] != want {
+		t.Fatalf("got %v, want [%s]: With must not mutate the receiver", logs, want)
+	}
+}
+
+type stringerOnly struct{}
+
+func (stringerOnly) String() string { return "stringer-value" }
+
+func TestLoggerFieldFallbackForErrorAndStringer(t *testing.T) {
+	var logs []string
+	shopifyfunctiontest.Run(t, "null", func() {
+		sf.NewLogger().Info("oops",
+			sf.F("err", errors.New("boom")),
+			sf.F("s", stringerOnly{}),
+			sf.F("fn", func() {}),
+		)
+		logs = sf.TestHostLogs()
+	})
+	want := "{\"level\":\"info\",\"msg\":\"oops\",\"err\":\"boom\",\"s\":\"stringer-value\",\"fn\":\"\\u003cunencodable\\u003e\"}"
+	if len(logs) != 1 || logs[0] != want {
+		t.Fatalf("got %v, want [%s]", logs, want)
+	}
+}
+
+func TestDefaultLoggerPackageFunctions(t *testing.T) {
+	var logs []string
+	shopifyfunctiontest.Run(t, "null", func() {
+		prev := sf.Default()
+		defer sf.SetDefault(prev)
+		sf.SetDefault(sf.NewLogger())
+
+		sf.Debug("d")
+		sf.Info("i")
+		sf.Warn("w")
+		sf.Error("e")
+		logs = sf.TestHostLogs()
+	})
+	want := []string{
+		`{"level":"debug","msg":"d"}`,
+		`{"level":"info","msg":"i"}`,
+		`{"level":"warn","msg":"w"}`,
+		`{"level":"error","msg":"e"}`,
+	}
+	if len(logs) != len(want) {
+		t.Fatalf("got %v, want %v", logs, want)
+	}
+	for i := range want {
+		if logs[i] != want[i] {
+			t.Fatalf("got %v, want %v", logs, want)
+		}
+	}
+}
+
+type plainEncoder struct{}
+
+func (plainEncoder) Encode(level sf.Level, msg string, fields []sf.Field) string {
+	return level.String() + ": " + msg
+}
+
+func TestLoggerWithEncoderUsesCustomEncoder(t *testing.T) {
+	var logs []string
+	shopifyfunctiontest.Run(t, "null", func() {
+		sf.NewLogger().WithEncoder(plainEncoder{}).Info("hi")
+		logs = sf.TestHostLogs()
+	})
+	want := "info: hi"
+	if len(logs) != 1 || logs[0] != want {
+		t.Fatalf("got %v, want [%s]", logs, want)
+	}
+}
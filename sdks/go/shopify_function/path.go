@@ -0,0 +1,179 @@
+package shopify_function
+
+import (
+	"strconv"
+	"strings"
+)
+
+// pathSegment is one step of a Path: either a field access (.name or
+// ['name']) or an array index ([n]).
+type pathSegment struct {
+	field string
+	index int
+	isIdx bool
+}
+
+// Path builds a JSONPath string for the "target" field of a function
+// error, e.g. Root().Field("cart").Index(0).Field("quantity") produces
+// "$.cart[0].quantity". Keys that aren't valid bare identifiers fall back
+// to bracket notation automatically.
+type Path struct {
+	segments []pathSegment
+}
+
+// Root returns the path "$", the root of the input document.
+func Root() Path {
+	return Path{}
+}
+
+// Field appends a property access to the path.
+func (p Path) Field(name string) Path {
+	segments := make([]pathSegment, len(p.segments), len(p.segments)+1)
+	copy(segments, p.segments)
+	segments = append(segments, pathSegment{field: name})
+	return Path{segments: segments}
+}
+
+// Index appends an array index to the path.
+func (p Path) Index(i int) Path {
+	segments := make([]pathSegment, len(p.segments), len(p.segments)+1)
+	copy(segments, p.segments)
+	segments = append(segments, pathSegment{index: i, isIdx: true})
+	return Path{segments: segments}
+}
+
+// String returns the JSONPath representation of the path.
+func (p Path) String() string {
+	var b strings.Builder
+	p.writeTo(&b)
+	return b.String()
+}
+
+// WriteTo renders the path and passes it to w as a byte slice in one
+// call, e.g. OutputStringBytes. The path is first rendered into a string
+// and then copied into that slice, so WriteTo allocates at least as much
+// as String does.
+func (p Path) WriteTo(w func(value []byte) error) error {
+	var b strings.Builder
+	p.writeTo(&b)
+	return w([]byte(b.String()))
+}
+
+func (p Path) writeTo(b *strings.Builder) {
+	b.WriteByte('$')
+	for _, seg := range p.segments {
+		if seg.isIdx {
+			b.WriteByte('[')
+			b.WriteString(strconv.Itoa(seg.index))
+			b.WriteByte(']')
+			continue
+		}
+		if isBareIdentifier(seg.field) {
+			b.WriteByte('.')
+			b.WriteString(seg.field)
+			continue
+		}
+		b.WriteString("['")
+		writeEscapedKey(b, seg.field)
+		b.WriteString("']")
+	}
+}
+
+func isBareIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r == '_':
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func writeEscapedKey(b *strings.Builder, key string) {
+	for _, r := range key {
+		switch r {
+		case '\'', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+}
+
+// functionError is a single entry of the "errors" array in a function's
+// output.
+type functionError struct {
+	localizedMessage string
+	target           Path
+}
+
+// ErrorsBuilder accumulates function errors and flushes them as the
+// {"errors": [...]} object expected in function output.
+type ErrorsBuilder struct {
+	errors []functionError
+}
+
+// NewErrorsBuilder returns an empty ErrorsBuilder.
+func NewErrorsBuilder() *ErrorsBuilder {
+	return &ErrorsBuilder{}
+}
+
+// Add records a localized message targeting the given path.
+func (b *ErrorsBuilder) Add(localizedMessage string, target Path) *ErrorsBuilder {
+	b.errors = append(b.errors, functionError{localizedMessage: localizedMessage, target: target})
+	return b
+}
+
+// Len returns the number of errors accumulated so far.
+func (b *ErrorsBuilder) Len() int {
+	return len(b.errors)
+}
+
+// Flush writes the accumulated errors as {"errors": [...]} via OutputObject
+// and OutputArray, then clears the builder.
+func (b *ErrorsBuilder) Flush() error {
+	if err := OutputObject(1); err != nil {
+		return err
+	}
+	if err := OutputString("errors"); err != nil {
+		return err
+	}
+	if err := OutputArray(uint32(len(b.errors))); err != nil {
+		return err
+	}
+	for _, e := range b.errors {
+		if err := OutputObject(2); err != nil {
+			return err
+		}
+		if err := OutputString("localizedMessage"); err != nil {
+			return err
+		}
+		if err := OutputString(e.localizedMessage); err != nil {
+			return err
+		}
+		if err := OutputString("target"); err != nil {
+			return err
+		}
+		if err := e.target.WriteTo(OutputStringBytes); err != nil {
+			return err
+		}
+		if err := OutputFinishObject(); err != nil {
+			return err
+		}
+	}
+	if err := OutputFinishArray(); err != nil {
+		return err
+	}
+	b.errors = nil
+	return OutputFinishObject()
+}
@@ -0,0 +1,87 @@
+package shopify_function_test
+
+import (
+	"testing"
+
+	sf "github.com/Shopify/shopify-function-wasm-api/sdks/go/shopify_function"
+	"github.com/Shopify/shopify-function-wasm-api/sdks/go/shopifyfunctiontest"
+)
+
+func TestPathBareIdentifierField(t *testing.T) {
+	got := sf.Root().Field("cart").String()
+	want := "$.cart"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestPathBracketFallbackForDottedKey(t *testing.T) {
+	got := sf.Root().Field("a.b").String()
+	want := "$['a.b']"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestPathEscapesQuoteAndBackslash(t *testing.T) {
+	got := sf.Root().Field(`it's\here`).String()
+	want := `$['it\'s\\here']`
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestPathIndexComposedWithField(t *testing.T) {
+	got := sf.Root().Field("cart").Field("lines").Index(0).Field("quantity").String()
+	want := "$.cart.lines[0].quantity"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestErrorsBuilderFlushEmpty(t *testing.T) {
+	out, _ := shopifyfunctiontest.Run(t, "null", func() {
+		if err := sf.NewErrorsBuilder().Flush(); err != nil {
+			t.Fatalf("Flush: %v", err)
+		}
+	})
+	want := `{"errors":[]}`
+	if out != want {
+		t.Fatalf("got %s, want %s", out, want)
+	}
+}
+
+func TestErrorsBuilderFlushMultiple(t *testing.T) {
+	out, _ := shopifyfunctiontest.Run(t, "null", func() {
+		err := sf.NewErrorsBuilder().
+			Add("too many", sf.Root().Field("cart")).
+			Add("not enough", sf.Root().Field("cart").Index(1)).
+			Flush()
+		if err != nil {
+			t.Fatalf("Flush: %v", err)
+		}
+	})
+	want := `{"errors":[{"localizedMessage":"too many","target":"$.cart"},{"localizedMessage":"not enough","target":"$.cart[1]"}]}`
+	if out != want {
+		t.Fatalf("got %s, want %s", out, want)
+	}
+}
+
+func TestErrorsBuilderLenAndReset(t *testing.T) {
+	b := sf.NewErrorsBuilder()
+	if b.Len() != 0 {
+		t.Fatalf("got %d, want 0", b.Len())
+	}
+	b.Add("oops", sf.Root())
+	if b.Len() != 1 {
+		t.Fatalf("got %d, want 1", b.Len())
+	}
+	shopifyfunctiontest.Run(t, "null", func() {
+		if err := b.Flush(); err != nil {
+			t.Fatalf("Flush: %v", err)
+		}
+	})
+	if b.Len() != 0 {
+		t.Fatalf("Flush should clear accumulated errors, got Len() = %d", b.Len())
+	}
+}
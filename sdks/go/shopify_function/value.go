@@ -36,6 +36,7 @@ type Value struct {
 
 // InputGet retrieves the root input value.
 func InputGet() Value {
+	resetPropCache()
 	return Value{raw: inputGet()}
 }
 
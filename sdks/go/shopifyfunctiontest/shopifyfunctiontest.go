@@ -0,0 +1,46 @@
+//go:build !wasm
+
+// Package shopifyfunctiontest is a host-side test harness for functions
+// written against shopify_function. It lets a function's entry point be
+// exercised with `go test` by swapping in a pure-Go implementation of the
+// wasm host (see shopify_function's !wasm build), so function authors
+// don't need a wasm32 build or a real Shopify Function host to drive
+// their code.
+package shopifyfunctiontest
+
+import (
+	"testing"
+
+	"github.com/Shopify/shopify-function-wasm-api/sdks/go/internal/hostvalue"
+	sf "github.com/Shopify/shopify-function-wasm-api/sdks/go/shopify_function"
+)
+
+// Run seeds the host with inputJSON as the input value, calls fn (typically
+// the body of the function's main), and returns the JSON written via the
+// Output* family of calls along with anything written via sf.Log. It
+// fails t if inputJSON isn't valid JSON or the function's output can't be
+// serialized back to JSON.
+func Run(t *testing.T, inputJSON string, fn func()) (outputJSON string, logs []string) {
+	t.Helper()
+
+	input, err := hostvalue.FromJSON([]byte(inputJSON))
+	if err != nil {
+		t.Fatalf("shopifyfunctiontest: invalid input JSON: %v", err)
+	}
+	sf.ResetTestHost(input)
+
+	fn()
+
+	out, err := hostvalue.ToJSON(sf.TestHostOutput())
+	if err != nil {
+		t.Fatalf("shopifyfunctiontest: function output can't be serialized as JSON: %v", err)
+	}
+	return out, sf.TestHostLogs()
+}
+
+// OutputTree returns the structured output tree written by the most
+// recent Run, without first serializing it to JSON: nil, bool, float64,
+// string, *hostvalue.Object, or []any.
+func OutputTree() any {
+	return sf.TestHostOutput()
+}